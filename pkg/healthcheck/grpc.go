@@ -0,0 +1,124 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// componentByServiceName maps a grpc.health.v1 service name to the internal
+// Component it reports on. The empty string, as in the standard protocol,
+// means the overall aggregated status.
+var componentByServiceName = map[string]Component{
+	"":                                 Default,
+	"jaeger.storage":                   Storage,
+	"jaeger.archive-storage":           ArchiveStorage,
+	"jaeger.sampling-strategy-storage": SamplingStorage,
+}
+
+// ServeGRPC starts a gRPC server on the specified port implementing the
+// standard grpc.health.v1.Health service, so Jaeger can be probed by
+// Kubernetes gRPC probes and service meshes without an HTTP detour.
+func (hc *HealthCheck) ServeGRPC(port int) (*HealthCheck, error) {
+	portStr := ":" + strconv.Itoa(port)
+	l, err := net.Listen("tcp", portStr)
+	if err != nil {
+		hc.logger.Error("Health Check gRPC server failed to listen", zap.Error(err))
+		return nil, err
+	}
+	hc.grpcServer = grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(hc.grpcServer, hc)
+	go func() {
+		if err := hc.grpcServer.Serve(l); err != nil {
+			hc.logger.Error("failed to serve gRPC health check", zap.Error(err))
+			hc.Set(Broken)
+		}
+	}()
+	hc.logger.Info("Health Check gRPC server started", zap.Int("grpc-port", port))
+	return hc, nil
+}
+
+// Check implements grpc_health_v1.HealthServer.
+func (hc *HealthCheck) Check(_ context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	c, known := componentByServiceName[req.Service]
+	if !known {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: hc.servingStatus(c)}, nil
+}
+
+// Watch implements grpc_health_v1.HealthServer, streaming status changes for
+// the requested service, driven off the same subscriber fan-out the
+// monitor() loop feeds, until the client disconnects.
+func (hc *HealthCheck) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	c, known := componentByServiceName[req.Service]
+	if !known {
+		return status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+
+	updates := hc.subscribe()
+	defer hc.unsubscribe(updates)
+
+	lastSent := hc.servingStatus(c)
+	if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: lastSent}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case msg, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if req.Service != "" && msg.comp != c {
+				continue
+			}
+			if current := hc.servingStatus(c); current != lastSent {
+				if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+					return err
+				}
+				lastSent = current
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// servingStatus maps a Component's current Status to the gRPC health
+// protocol's SERVING/NOT_SERVING vocabulary. Degraded is reported as
+// SERVING, consistent with "/" and "/health/ready" returning HTTP 200 for
+// it: the service is still handling requests, just not at full health.
+func (hc *HealthCheck) servingStatus(c Component) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	var s Status
+	if c == Default {
+		s = hc.Get()
+	} else {
+		hc.mu.RLock()
+		s = hc.comstat[c].status
+		hc.mu.RUnlock()
+	}
+	if s == Ready || s == Degraded {
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+}
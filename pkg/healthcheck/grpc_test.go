@@ -0,0 +1,176 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialHealthClient starts hc's gRPC health service on an in-process
+// bufconn listener and returns a client connected to it, the way a real
+// gRPC health probe would talk to it over the network.
+func dialHealthClient(t *testing.T, hc *HealthCheck) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, hc)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestServingStatus(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{
+			Storage:        Required,
+			ArchiveStorage: Degrades,
+		}),
+	)
+	defer hc.Close()
+
+	report := func(c Component, s Status) { hc.receptor <- ComponentStatus{comp: c, stat: s} }
+
+	report(Storage, Ready)
+	report(ArchiveStorage, Ready)
+	waitForStatus(t, hc, Ready, time.Second)
+	if got := hc.servingStatus(Default); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("Ready aggregate reported as %s", got)
+	}
+
+	// Degraded must still be SERVING: "/" and "/health/ready" return HTTP 200
+	// for it since the service keeps handling requests.
+	report(ArchiveStorage, Unavailable)
+	waitForStatus(t, hc, Degraded, time.Second)
+	if got := hc.servingStatus(Default); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("Degraded aggregate reported as %s, want SERVING", got)
+	}
+
+	report(Storage, Unavailable)
+	waitForStatus(t, hc, Unavailable, time.Second)
+	if got := hc.servingStatus(Default); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Unavailable aggregate reported as %s, want NOT_SERVING", got)
+	}
+}
+
+// TestGRPCHealth_Check drives the grpc.health.v1 Check RPC over a real
+// in-process gRPC connection, including the unknown-service NotFound path.
+func TestGRPCHealth_Check(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{Storage: Required}),
+	)
+	defer hc.Close()
+
+	client := dialHealthClient(t, hc)
+	ctx := context.Background()
+
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: ""})
+	if err != nil {
+		t.Fatalf("Check(\"\"): %v", err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Check(\"\") before any report = %s, want NOT_SERVING", resp.Status)
+	}
+
+	hc.receptor <- ComponentStatus{comp: Storage, stat: Ready}
+	waitForStatus(t, hc, Ready, time.Second)
+	resp, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "jaeger.storage"})
+	if err != nil {
+		t.Fatalf("Check(\"jaeger.storage\"): %v", err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("Check(\"jaeger.storage\") = %s, want SERVING", resp.Status)
+	}
+
+	_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "not-a-real-service"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Check(unknown service) error = %v, want codes.NotFound", err)
+	}
+}
+
+// TestGRPCHealth_Watch drives the grpc.health.v1 Watch RPC over a real
+// in-process gRPC connection and asserts duplicate reports of the same
+// effective status don't produce duplicate sends on the stream.
+func TestGRPCHealth_Watch(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{Storage: Required}),
+	)
+	defer hc.Close()
+
+	client := dialHealthClient(t, hc)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: ""})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	recv := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("stream.Recv: %v", err)
+		}
+		return resp.Status
+	}
+
+	if got := recv(); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("initial Watch status = %s, want NOT_SERVING", got)
+	}
+
+	report := func(s Status) { hc.receptor <- ComponentStatus{comp: Storage, stat: s} }
+
+	report(Ready)
+	if got := recv(); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("Watch status after Ready = %s, want SERVING", got)
+	}
+
+	// Duplicate Ready reports must not produce additional sends: the next
+	// thing off the stream should be the real Unavailable transition, not
+	// another (redundant) SERVING.
+	report(Ready)
+	report(Ready)
+	report(Unavailable)
+	if got := recv(); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("Watch status after Unavailable = %s, want NOT_SERVING (duplicate Ready reports leaked a send)", got)
+	}
+}
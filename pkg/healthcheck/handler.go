@@ -16,16 +16,34 @@ package healthcheck
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"github.com/jaegertracing/jaeger/pkg/version"
 )
 
+const (
+	// heartbeatInterval is how often monitor() stamps its liveness heartbeat,
+	// independent of whether any component status changes arrive.
+	heartbeatInterval = 5 * time.Second
+	// livenessTimeout is how stale the heartbeat can get before /health/alive
+	// considers the monitor loop wedged.
+	livenessTimeout = 3 * heartbeatInterval
+	// noPendingStatus is the sentinel value of pendingStatus before
+	// setEffective has ever run, distinct from any real Status.
+	noPendingStatus = -1
+)
+
 // Status represents the state of the service.
 type Status int
 
@@ -38,6 +56,12 @@ const (
 	Broken
 	// Fail just indicates a failure
 	Fail
+	// Draining indicates the service is shutting down and should be taken
+	// out of rotation, but the process itself is still alive
+	Draining
+	// Degraded indicates a Degrades component is Unavailable: the service
+	// keeps serving but is not at full health
+	Degraded
 )
 
 func (s Status) String() string {
@@ -48,11 +72,29 @@ func (s Status) String() string {
 		return "ready"
 	case Broken:
 		return "broken"
+	case Draining:
+		return "draining"
+	case Degraded:
+		return "degraded"
 	default:
 		return "unknown"
 	}
 }
 
+// jsonStatus renders the status using the healthy/unhealthy vocabulary of
+// the JSON health endpoint, following the shape of Harbor's
+// OverallHealthStatus rather than the internal Status.String() names.
+func (s Status) jsonStatus() string {
+	switch s {
+	case Ready:
+		return "healthy"
+	case Degraded:
+		return "degraded"
+	default:
+		return "unhealthy"
+	}
+}
+
 // Component value is designated for all the components under healthcheck.
 type Component uint32
 
@@ -84,24 +126,87 @@ func (c Component) String() string {
 	}
 }
 
+// Criticality describes how much a Component's unavailability should affect
+// the aggregate Status, modeled after OpenTelemetry's component-status
+// semantics.
+type Criticality int
+
+const (
+	// Required components being Unavailable makes the aggregate Unavailable.
+	Required Criticality = iota
+	// Degrades components being Unavailable makes the aggregate Degraded.
+	Degrades
+	// Informational components never affect the aggregate Status.
+	Informational
+)
+
 // ComponentStatus is a message coming from each event of the components
 type ComponentStatus struct {
 	comp Component
 	stat Status
+	err  error
+}
+
+// componentHealth is what we keep per Component so the JSON endpoint can
+// report more than just the current Status.
+type componentHealth struct {
+	status  Status
+	err     error
+	updated time.Time
 }
 
 // HealthCheck provides an HTTP endpoint that returns the health status of the service
 type HealthCheck struct {
-	state   int32 // atomic, keep at the top to be word-aligned
-	logger  *zap.Logger
-	mapping map[Status]int
-	server  *http.Server
-	comstat map[Component]Status
-	desired []Component
+	state         int32  // atomic, keep at the top to be word-aligned
+	lastHeartbeat int64  // atomic, unix nanoseconds of the last monitor() tick
+	debounceGen   uint64 // atomic, bumped only when setEffective sees an actual change
+	pendingStatus int32  // atomic, last effective Status computed by setEffective, or noPendingStatus
+	draining      int32  // atomic, 1 once Drain() has been called
+
+	logger      *zap.Logger
+	mapping     map[Status]int
+	server      *http.Server
+	desired     map[Component]Criticality
+	hysteresis  time.Duration
+	drainPeriod time.Duration
+
+	drained   chan struct{} // closed once the servers are actually shut down
+	closeOnce sync.Once
+	closeErr  error
+
+	livenessProbes []func() error
+
+	grpcServer *grpc.Server
+
+	probes    []*proberRegistration
+	probeDone chan struct{} // closed by closeServers to stop every runProbe goroutine
+
+	mu      sync.RWMutex // guards comstat
+	comstat map[Component]componentHealth
+
+	subMu       sync.Mutex // guards subscribers
+	subscribers map[<-chan ComponentStatus]chan ComponentStatus
+
+	applyMu sync.Mutex // serializes Drain's entry into Draining against setEffective's applies, see setEffective
 
 	receptor chan ComponentStatus
 }
 
+// overallHealthStatus is the JSON body served by /health/status, modeled
+// after Harbor's OverallHealthStatus.
+type overallHealthStatus struct {
+	Status     string           `json:"status"`
+	Components []componentEntry `json:"components"`
+}
+
+// componentEntry is the per-component entry within overallHealthStatus.
+type componentEntry struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Updated string `json:"updated,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
 // Option is a functional option for passing parameters to New()
 type Option func(*HealthCheck)
 
@@ -112,20 +217,92 @@ func Logger(logger *zap.Logger) Option {
 	}
 }
 
-// SetDesired is to specify the set of the Components to be up. If all of them are ready, we are ready.
-func SetDesired(cs []Component) Option {
+// SetDesired specifies the set of Components that participate in the
+// aggregated Status, along with how critical each one is: a Required
+// component going Unavailable makes the aggregate Unavailable, a Degrades
+// component going Unavailable makes it Degraded, and an Informational
+// component never affects the aggregate.
+func SetDesired(cs map[Component]Criticality) Option {
 	return func(hc *HealthCheck) {
 		hc.desired = cs
 	}
 }
 
-// SetReceptor sets a channel to send ComponentStatus which is created at initialization phase outside of this package.
+// SetReceptor sets the channel components report their ComponentStatus on,
+// for callers that want to hold onto that channel themselves (e.g. to send
+// from outside a StatusReporter). If not called, New creates one internally,
+// so RegisterProbe and GetStatusReporter work without it.
 func SetReceptor(rec chan ComponentStatus) Option {
 	return func(hc *HealthCheck) {
 		hc.receptor = rec
 	}
 }
 
+// LivenessProbe registers an additional probe that must return nil for
+// /health/alive to report the process as alive, e.g. checking that the
+// reporter channel isn't blocked. It is consulted in addition to the
+// monitor() heartbeat.
+func LivenessProbe(p func() error) Option {
+	return func(hc *HealthCheck) {
+		hc.livenessProbes = append(hc.livenessProbes, p)
+	}
+}
+
+// RegisterProbe wires up an active Prober for Component c: it is checked
+// every interval, bounded by timeout, and its result is funneled through the
+// same reporting path as a self-reported component status, so aggregation
+// logic is unchanged. By default the component only flips state after a
+// single failing or passing check; pass Thresholds to require consecutive
+// results before flipping, similar to Caddy's active health checks.
+func RegisterProbe(c Component, interval, timeout time.Duration, p Prober, opts ...ProbeOption) Option {
+	reg := &proberRegistration{
+		comp:               c,
+		interval:           interval,
+		timeout:            timeout,
+		prober:             p,
+		healthyThreshold:   1,
+		unhealthyThreshold: 1,
+	}
+	for _, opt := range opts {
+		opt(reg)
+	}
+	return func(hc *HealthCheck) {
+		hc.probes = append(hc.probes, reg)
+	}
+}
+
+// ProbeOption customizes a probe registered via RegisterProbe.
+type ProbeOption func(*proberRegistration)
+
+// Thresholds sets how many consecutive healthy/unhealthy probe results are
+// required before the component's reported status actually flips, so one
+// transient failure doesn't flap the whole aggregate. Both default to 1.
+func Thresholds(healthy, unhealthy int) ProbeOption {
+	return func(r *proberRegistration) {
+		r.healthyThreshold = healthy
+		r.unhealthyThreshold = unhealthy
+	}
+}
+
+// Hysteresis sets how long an aggregate status transition must remain the
+// most recently computed one before it is actually applied via Set(), so a
+// flapping component doesn't spam state changes and subscribers. Defaults
+// to 0, i.e. apply every transition immediately.
+func Hysteresis(d time.Duration) Option {
+	return func(hc *HealthCheck) {
+		hc.hysteresis = d
+	}
+}
+
+// DrainPeriod sets how long Drain waits, after flipping readiness to
+// not-ready, before the underlying servers are actually shut down. Defaults
+// to 0, i.e. shut down as soon as Drain is called.
+func DrainPeriod(d time.Duration) Option {
+	return func(hc *HealthCheck) {
+		hc.drainPeriod = d
+	}
+}
+
 // New creates a HealthCheck with the specified initial state.
 func New(state Status, options ...Option) *HealthCheck {
 	hc := &HealthCheck{
@@ -133,7 +310,12 @@ func New(state Status, options ...Option) *HealthCheck {
 		mapping: map[Status]int{
 			Unavailable: http.StatusServiceUnavailable,
 			Ready:       http.StatusNoContent,
+			Broken:      http.StatusServiceUnavailable,
+			Fail:        http.StatusServiceUnavailable,
+			Draining:    http.StatusServiceUnavailable,
+			Degraded:    http.StatusOK,
 		},
+		pendingStatus: noPendingStatus,
 	}
 	for _, option := range options {
 		option(hc)
@@ -141,8 +323,18 @@ func New(state Status, options ...Option) *HealthCheck {
 	if hc.logger == nil {
 		hc.logger = zap.NewNop()
 	}
-	hc.comstat = make(map[Component]Status, len(hc.desired))
+	if hc.receptor == nil {
+		hc.receptor = make(chan ComponentStatus, 16)
+	}
+	hc.comstat = make(map[Component]componentHealth, len(hc.desired))
+	hc.subscribers = make(map[<-chan ComponentStatus]chan ComponentStatus)
+	hc.drained = make(chan struct{})
+	hc.probeDone = make(chan struct{})
+	hc.heartbeat()
 	go hc.monitor()
+	for _, reg := range hc.probes {
+		go hc.runProbe(reg)
+	}
 	return hc
 }
 
@@ -169,25 +361,195 @@ func (hc *HealthCheck) serveWithListener(l net.Listener) {
 	}()
 }
 
-// Close stops the HTTP server
+// Drain transitions the HealthCheck into the Draining state: the readiness
+// endpoint immediately starts reporting not-ready while the liveness
+// endpoint keeps reporting the process as alive, and after DrainPeriod the
+// HTTP (and gRPC, if started) servers are actually shut down. This gives
+// kube-proxy time to propagate the NotReady state across the cluster before
+// sockets close, so in-flight requests aren't dropped on rollout. Calling
+// Drain more than once has no additional effect.
+func (hc *HealthCheck) Drain() {
+	if !atomic.CompareAndSwapInt32(&hc.draining, 0, 1) {
+		return
+	}
+	hc.logger.Info("Health Check entering drain phase", zap.Duration("drain-period", hc.drainPeriod))
+	// Serialized against setEffective's and Set's own critical sections:
+	// draining is set before this applies Draining, but the CAS above and
+	// the apply aren't atomic together, so without this lock a setEffective
+	// or Set call already past its own draining check could still apply()
+	// right after this and undo it.
+	hc.applyMu.Lock()
+	hc.setLocked(Draining)
+	hc.applyMu.Unlock()
+	go func() {
+		time.Sleep(hc.drainPeriod)
+		hc.closeOnce.Do(func() {
+			hc.closeErr = hc.closeServers()
+			close(hc.drained)
+		})
+	}()
+}
+
+// Close stops the HTTP server and, if ServeGRPC was called, the gRPC
+// server. If Drain has not already been called, Close triggers it first, so
+// callers that skip the explicit SIGTERM/Drain dance still get DrainPeriod
+// before sockets close.
 func (hc *HealthCheck) Close() error {
-	return hc.server.Shutdown(context.Background())
+	hc.Drain()
+	<-hc.drained
+	return hc.closeErr
+}
+
+// closeServers performs the actual server shutdown once draining is done.
+func (hc *HealthCheck) closeServers() error {
+	close(hc.probeDone)
+	hc.closeSubscribers()
+	if hc.grpcServer != nil {
+		hc.grpcServer.GracefulStop()
+	}
+	if hc.server != nil {
+		return hc.server.Shutdown(context.Background())
+	}
+	return nil
 }
 
 // httpHandler creates a new HTTP handler.
 func (hc *HealthCheck) httpHandler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(hc.mapping[hc.Get()])
+		state := hc.Get()
+		w.WriteHeader(hc.mapping[state])
 		// this is written only for response with an entity, so, it won't be used for a 204 - No content
-		w.Write([]byte("Server not available"))
+		if state == Degraded {
+			w.Write([]byte("Service is degraded"))
+		} else {
+			w.Write([]byte("Server not available"))
+		}
 	})
+	mux.HandleFunc("/health/status", hc.statusJSONHandler)
+	mux.HandleFunc("/health/alive", hc.aliveHandler)
+	mux.HandleFunc("/health/ready", hc.readyHandler)
 	version.RegisterHandler(mux, hc.logger)
 	return mux
 }
 
-// Set a new health check status
+// aliveHandler reports whether the process itself is alive: the monitor()
+// goroutine is still heartbeating and any registered LivenessProbe passes.
+// Unlike readyHandler, it does not depend on desired-component aggregation,
+// so a downstream storage outage won't get this pod restarted by Kubernetes.
+func (hc *HealthCheck) aliveHandler(w http.ResponseWriter, _ *http.Request) {
+	if err := hc.isAlive(); err != nil {
+		hc.logger.Error("Liveness probe failed", zap.Error(err))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// readyHandler reports the desired-component aggregation, i.e. the same
+// status as "/" but without the legacy response body. A Degraded aggregate
+// still reports 200, with a warning body, since the service is serving
+// requests just not at full health.
+func (hc *HealthCheck) readyHandler(w http.ResponseWriter, _ *http.Request) {
+	state := hc.Get()
+	w.WriteHeader(hc.mapping[state])
+	if state == Degraded {
+		w.Write([]byte("Service is degraded"))
+	}
+}
+
+// isAlive returns an error if the monitor() loop appears wedged or any
+// registered LivenessProbe fails.
+func (hc *HealthCheck) isAlive() error {
+	last := atomic.LoadInt64(&hc.lastHeartbeat)
+	if age := time.Since(time.Unix(0, last)); age > livenessTimeout {
+		return fmt.Errorf("healthcheck monitor loop has not heartbeat in %s", age)
+	}
+	for _, probe := range hc.livenessProbes {
+		if err := probe(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heartbeat stamps the current time as the monitor loop's last liveness tick.
+func (hc *HealthCheck) heartbeat() {
+	atomic.StoreInt64(&hc.lastHeartbeat, time.Now().UnixNano())
+}
+
+// statusJSONHandler serves the aggregate and per-component health as JSON,
+// e.g. {"status":"unhealthy","components":[{"name":"storage","status":"unhealthy","error":"..."}]}
+// using the same state->HTTP-code mapping as "/" and "/health/ready", so a
+// Degraded aggregate is still 200, not 503.
+func (hc *HealthCheck) statusJSONHandler(w http.ResponseWriter, _ *http.Request) {
+	state := hc.Get()
+	overall := hc.overallStatus(state)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(hc.mapping[state])
+	json.NewEncoder(w).Encode(overall)
+}
+
+// overallStatus builds the overallHealthStatus body served by /health/status.
+// It walks hc.desired, not hc.comstat, so a desired component that has never
+// self-reported still shows up (as unavailable) instead of silently missing
+// from the list.
+func (hc *HealthCheck) overallStatus(state Status) overallHealthStatus {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	components := make([]componentEntry, 0, len(hc.desired))
+	for c := range hc.desired {
+		h, reported := hc.comstat[c]
+		if !reported {
+			h = componentHealth{status: Unavailable}
+		}
+		entry := componentEntry{
+			Name:   c.String(),
+			Status: h.status.jsonStatus(),
+		}
+		if reported {
+			entry.Updated = h.updated.Format(time.RFC3339)
+		}
+		if h.err != nil {
+			entry.Error = h.err.Error()
+		}
+		components = append(components, entry)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	return overallHealthStatus{
+		Status:     state.jsonStatus(),
+		Components: components,
+	}
+}
+
+// Set the health check status directly, bypassing the Criticality
+// aggregation in checkComponent/setEffective (e.g. Broken on a listener
+// failure). Once Drain has been called, draining always wins: every Set
+// other than Drain's own Draining transition is ignored, the same
+// invariant setEffective's applyIfNotDraining enforces for aggregated
+// transitions.
 func (hc *HealthCheck) Set(state Status) {
+	hc.applyMu.Lock()
+	defer hc.applyMu.Unlock()
+	if atomic.LoadInt32(&hc.draining) != 0 && state != Draining {
+		return
+	}
+	hc.setLocked(state)
+}
+
+// setLocked resets the debounce bookkeeping and applies state. Callers must
+// already hold applyMu; Drain uses this directly to avoid relocking it.
+func (hc *HealthCheck) setLocked(state Status) {
+	atomic.StoreInt32(&hc.pendingStatus, noPendingStatus)
+	atomic.AddUint64(&hc.debounceGen, 1)
+	hc.apply(state)
+}
+
+// apply commits state and logs the transition. setEffective and setLocked
+// call this directly instead of Set, since they manage
+// pendingStatus/debounceGen (and, via applyMu, the draining check)
+// themselves, and Set's own locking would deadlock if re-entered.
+func (hc *HealthCheck) apply(state Status) {
 	atomic.StoreInt32(&hc.state, int32(state))
 	hc.logger.Info("Health Check state change", zap.Stringer("status", hc.Get()))
 }
@@ -202,49 +564,200 @@ func (hc *HealthCheck) Ready() {
 	hc.Set(Ready)
 }
 
-// Monitor the receptor's report coming from components on ready or unready
+// Monitor the receptor's report coming from components on ready or unready.
+// It also heartbeats on every tick, even with no component status changes,
+// so /health/alive can detect a wedged aggregator.
 func (hc *HealthCheck) monitor() {
-	for msg := range hc.receptor {
-		hc.logger.Info("Component health state change", zap.Stringer("component", msg.comp), zap.Stringer("state", msg.stat))
-		hc.comstat[msg.comp] = msg.stat
-		hc.checkComponent()
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-hc.receptor:
+			if !ok {
+				return
+			}
+			if msg.err != nil {
+				hc.logger.Info("Component health state change", zap.Stringer("component", msg.comp), zap.Stringer("state", msg.stat), zap.Error(msg.err))
+			} else {
+				hc.logger.Info("Component health state change", zap.Stringer("component", msg.comp), zap.Stringer("state", msg.stat))
+			}
+			hc.mu.Lock()
+			hc.comstat[msg.comp] = componentHealth{status: msg.stat, err: msg.err, updated: time.Now()}
+			hc.mu.Unlock()
+			hc.broadcast(msg)
+			hc.checkComponent()
+			hc.heartbeat()
+		case <-ticker.C:
+			hc.heartbeat()
+		}
 	}
 }
 
-// All the goodies there? Let's go!
+// checkComponent recomputes the aggregate Status from the current
+// per-component state and the registered Criticality of each desired
+// Component: any Required component Unavailable makes the aggregate
+// Unavailable; failing that, any Degrades component Unavailable makes it
+// Degraded; Informational components never affect the aggregate. Once
+// Drain has been called, component reports no longer affect the aggregate -
+// it stays Draining until the servers actually shut down.
 func (hc *HealthCheck) checkComponent() {
-	ok := true
-	for _, c := range hc.desired {
-		if hc.comstat[c] != Ready {
-			ok = false
-			break
+	if atomic.LoadInt32(&hc.draining) != 0 {
+		return
+	}
+	effective := Ready
+	hc.mu.RLock()
+	for c, crit := range hc.desired {
+		if hc.comstat[c].status == Ready {
+			continue
+		}
+		switch crit {
+		case Required:
+			hc.mu.RUnlock()
+			hc.setEffective(Unavailable)
+			return
+		case Degrades:
+			effective = Degraded
+		case Informational:
+			// never affects the aggregate
 		}
 	}
+	hc.mu.RUnlock()
+	hc.setEffective(effective)
+}
+
+// setEffective applies a newly computed aggregate Status, debouncing rapid
+// flips by waiting out hc.hysteresis before calling Set(); a superseding
+// transition within that window cancels this one. checkComponent calls this
+// on every received message, including ones that recompute the same
+// effective Status (e.g. a probe re-reporting Ready on every tick), so a
+// no-op recomputation must not reset or re-arm the debounce timer - only an
+// actual change in the effective Status does.
+func (hc *HealthCheck) setEffective(s Status) {
+	if atomic.SwapInt32(&hc.pendingStatus, int32(s)) == int32(s) {
+		return // same effective Status as last time; nothing changed
+	}
+	gen := atomic.AddUint64(&hc.debounceGen, 1)
+	if hc.hysteresis <= 0 {
+		hc.applyIfNotDraining(s)
+		return
+	}
+	time.AfterFunc(hc.hysteresis, func() {
+		if atomic.LoadUint64(&hc.debounceGen) != gen {
+			return // a newer transition superseded this one
+		}
+		hc.applyIfNotDraining(s)
+	})
+}
+
+// applyIfNotDraining applies s unless Drain() has won the race to enter
+// Draining, serialized against Drain's own apply under applyMu so the
+// check-then-apply can't interleave with it.
+func (hc *HealthCheck) applyIfNotDraining(s Status) {
+	hc.applyMu.Lock()
+	defer hc.applyMu.Unlock()
+	if atomic.LoadInt32(&hc.draining) != 0 {
+		return // Drain raced us here; once draining, it always wins
+	}
+	hc.apply(s)
+	hc.broadcastOverall(s)
+}
+
+// broadcastOverall fans the debounced aggregate Status out to subscribers,
+// reported under the Default component to distinguish it from per-component
+// updates.
+func (hc *HealthCheck) broadcastOverall(s Status) {
+	hc.broadcast(ComponentStatus{comp: Default, stat: s})
+}
+
+// Subscribe returns a channel of ComponentStatus updates - both raw
+// per-component reports and the debounced aggregate status, reported under
+// the Default component - so other subsystems (metrics exporters, the gRPC
+// Watch implementation) can consume the stream without stealing messages
+// from the channel passed to SetReceptor. The channel is buffered and
+// non-blocking: a slow consumer misses updates rather than stalling
+// monitor(). Callers that stop reading before the HealthCheck is closed
+// must call Unsubscribe to release the channel.
+func (hc *HealthCheck) Subscribe() <-chan ComponentStatus {
+	return hc.subscribe()
+}
+
+// Unsubscribe releases a channel previously returned by Subscribe, closing
+// it so the caller's range/receive loop ends and removing it from the
+// broadcast set.
+func (hc *HealthCheck) Unsubscribe(ch <-chan ComponentStatus) {
+	hc.unsubscribe(ch)
+}
+
+// subscribe returns a channel fed with every ComponentStatus that arrives
+// over the receptor, for internal consumers (e.g. the gRPC Watch RPC) that
+// need their own stream instead of racing the monitor() loop for messages
+// off hc.receptor. The channel is buffered and non-blocking: a slow reader
+// misses updates rather than stalling monitor().
+func (hc *HealthCheck) subscribe() chan ComponentStatus {
+	ch := make(chan ComponentStatus, 8)
+	hc.subMu.Lock()
+	hc.subscribers[ch] = ch
+	hc.subMu.Unlock()
+	return ch
+}
+
+// unsubscribe removes and closes a channel previously returned by subscribe,
+// identifying it by its receive-only view so external Unsubscribe callers
+// can pass back exactly what Subscribe gave them.
+func (hc *HealthCheck) unsubscribe(ch <-chan ComponentStatus) {
+	hc.subMu.Lock()
+	full, ok := hc.subscribers[ch]
+	delete(hc.subscribers, ch)
+	hc.subMu.Unlock()
 	if ok {
-		hc.Set(Ready)
-	} else {
-		hc.Set(Unavailable)
+		close(full)
+	}
+}
+
+// broadcast fans a ComponentStatus out to every subscriber.
+func (hc *HealthCheck) broadcast(msg ComponentStatus) {
+	hc.subMu.Lock()
+	defer hc.subMu.Unlock()
+	for _, ch := range hc.subscribers {
+		select {
+		case ch <- msg:
+		default:
+		}
 	}
 }
 
-// StatusReporter is called on an availablity change event.
-type StatusReporter func(Status)
+// closeSubscribers closes every remaining subscriber channel, for callers
+// that never explicitly Unsubscribe before the HealthCheck is closed.
+func (hc *HealthCheck) closeSubscribers() {
+	hc.subMu.Lock()
+	defer hc.subMu.Unlock()
+	for key, ch := range hc.subscribers {
+		close(ch)
+		delete(hc.subscribers, key)
+	}
+}
+
+// StatusReporter is called on an availability change event, with an
+// optional error describing what drove the component to its new Status
+// (e.g. connection refused, timeout).
+type StatusReporter func(Status, error)
 
 // GetNullStatusReporter returns the mock StatusReporter.
-func GetNullStatusReporter() func(Status) {
-	return func(_ Status) {}
+func GetNullStatusReporter() StatusReporter {
+	return func(_ Status, _ error) {}
 }
 
 // GetStatusReporter is a vending machine of gifts for the components. Each component talks to their own teddy bear and he reports their confess for us.
-func (hc *HealthCheck) GetStatusReporter(c Component) func(Status) {
-	return func(stat Status) {
-        if hc.receptor == nil {
-            hc.logger.Warn("No channel for component status report")
-            return
-        }
+func (hc *HealthCheck) GetStatusReporter(c Component) StatusReporter {
+	return func(stat Status, err error) {
+		if hc.receptor == nil {
+			hc.logger.Warn("No channel for component status report")
+			return
+		}
 		hc.receptor <- ComponentStatus{
 			stat: stat,
 			comp: c,
+			err:  err,
 		}
 	}
 }
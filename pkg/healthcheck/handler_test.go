@@ -0,0 +1,359 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForStatus polls hc.Get() until it matches want or the timeout elapses.
+func waitForStatus(t *testing.T, hc *HealthCheck, want Status, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		if hc.Get() == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status never reached %s, still %s", want, hc.Get())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCheckComponent_Criticality(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{
+			Storage:         Required,
+			ArchiveStorage:  Degrades,
+			SamplingStorage: Informational,
+		}),
+	)
+	defer hc.Close()
+
+	report := func(c Component, s Status) {
+		hc.receptor <- ComponentStatus{comp: c, stat: s}
+	}
+
+	// Everything Ready -> aggregate Ready.
+	report(Storage, Ready)
+	report(ArchiveStorage, Ready)
+	report(SamplingStorage, Ready)
+	waitForStatus(t, hc, Ready, time.Second)
+
+	// Informational-only failure must not move the aggregate off Ready.
+	report(SamplingStorage, Unavailable)
+	time.Sleep(20 * time.Millisecond)
+	if got := hc.Get(); got != Ready {
+		t.Fatalf("informational failure affected aggregate: got %s", got)
+	}
+
+	// A Degrades component failing makes the aggregate Degraded, not Unavailable.
+	report(ArchiveStorage, Unavailable)
+	waitForStatus(t, hc, Degraded, time.Second)
+
+	// A Required component failing takes priority and makes it Unavailable.
+	report(Storage, Unavailable)
+	waitForStatus(t, hc, Unavailable, time.Second)
+
+	// Recovering the Required component restores Degraded (ArchiveStorage is
+	// still down).
+	report(Storage, Ready)
+	waitForStatus(t, hc, Degraded, time.Second)
+}
+
+func TestHysteresis_AppliesOnRealChange(t *testing.T) {
+	receptor := make(chan ComponentStatus, 64)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{Storage: Required}),
+		Hysteresis(20*time.Millisecond),
+	)
+	defer hc.Close()
+
+	report := func(s Status) { hc.receptor <- ComponentStatus{comp: Storage, stat: s} }
+
+	// A flurry of identical "healthy" reports, as chunk0-4's active probes
+	// send on every tick once the healthy threshold is met, must not starve
+	// out the debounce timer for a later, genuine transition.
+	for i := 0; i < 10; i++ {
+		report(Ready)
+		time.Sleep(2 * time.Millisecond)
+	}
+	waitForStatus(t, hc, Ready, time.Second)
+
+	report(Unavailable)
+	waitForStatus(t, hc, Unavailable, time.Second)
+}
+
+func TestDrain_CancelsPendingHysteresis(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{Storage: Required}),
+		Hysteresis(300*time.Millisecond),
+		DrainPeriod(10*time.Millisecond),
+	)
+	defer hc.Close()
+
+	// Schedule a debounced Ready transition, then Drain before it fires. The
+	// pending timer must not be allowed to clobber Draining once it does.
+	hc.receptor <- ComponentStatus{comp: Storage, stat: Ready}
+	time.Sleep(50 * time.Millisecond)
+	hc.Drain()
+
+	time.Sleep(500 * time.Millisecond)
+	if got := hc.Get(); got != Draining && got != Broken {
+		t.Fatalf("pending hysteresis timer clobbered drain: got %s", got)
+	}
+}
+
+func TestDrain_WinsOverExternalSet(t *testing.T) {
+	hc := New(Ready)
+	defer hc.Close()
+
+	hc.Drain()
+	// A caller reaching for the public Ready()/Set() shortcuts after Drain
+	// has already been called (e.g. an embedder's own shutdown-adjacent
+	// code racing the SIGTERM handler) must not be able to un-drain it.
+	hc.Ready()
+	hc.Set(Broken)
+	hc.Set(Unavailable)
+
+	if got := hc.Get(); got != Draining {
+		t.Fatalf("external Set after Drain() clobbered it: got %s, want draining", got)
+	}
+}
+
+func TestDrain_RaceWithZeroHysteresisApply(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		receptor := make(chan ComponentStatus, 16)
+		hc := New(
+			Unavailable,
+			SetReceptor(receptor),
+			SetDesired(map[Component]Criticality{Storage: Required}),
+		)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			hc.receptor <- ComponentStatus{comp: Storage, stat: Ready}
+		}()
+		go func() {
+			defer wg.Done()
+			hc.Drain()
+		}()
+		wg.Wait()
+
+		// Give the racing setEffective a moment to finish applying, then the
+		// Status must settle on Draining, never get pulled back to Ready.
+		time.Sleep(5 * time.Millisecond)
+		if got := hc.Get(); got != Draining && got != Broken {
+			hc.Close()
+			t.Fatalf("iteration %d: Drain lost the race, status is %s", i, got)
+		}
+		hc.Close()
+	}
+}
+
+func TestSet_ReaffirmingAfterExternalSetRecovers(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{Storage: Required}),
+	)
+	defer hc.Close()
+
+	report := func(s Status) { hc.receptor <- ComponentStatus{comp: Storage, stat: s} }
+
+	report(Ready)
+	waitForStatus(t, hc, Ready, time.Second)
+
+	// Simulate an out-of-band Set, as Serve's listener-failure handler does
+	// with Broken, bypassing the criticality aggregation entirely.
+	hc.Set(Broken)
+	waitForStatus(t, hc, Broken, time.Second)
+
+	// Re-affirming the same effective Status as before the external Set
+	// must still take effect, not get deduped against the now-stale
+	// pending value.
+	report(Ready)
+	waitForStatus(t, hc, Ready, time.Second)
+}
+
+// TestHTTPHandlers_Endpoints drives /health/alive, /health/ready and
+// /health/status over real HTTP requests, the way Kubernetes probes and
+// orchestrators actually consume them, rather than asserting on internal
+// state directly.
+func TestHTTPHandlers_Endpoints(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{
+			Storage:        Required,
+			ArchiveStorage: Degrades,
+		}),
+	)
+	defer hc.Close()
+
+	srv := httptest.NewServer(hc.httpHandler())
+	defer srv.Close()
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		return resp
+	}
+
+	// Liveness never depends on component aggregation: the process is alive
+	// even before any component has reported.
+	if resp := get("/health/alive"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("/health/alive = %d, want 200", resp.StatusCode)
+	}
+
+	// Readiness does depend on aggregation: nothing has reported yet, so the
+	// aggregate is still Unavailable.
+	if resp := get("/health/ready"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("/health/ready before any report = %d, want 503", resp.StatusCode)
+	}
+
+	// /health/status must list a desired-but-never-reported component as
+	// unavailable instead of omitting it.
+	resp := get("/health/status")
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("/health/status before any report = %d, want 503", resp.StatusCode)
+	}
+	var overall overallHealthStatus
+	if err := json.NewDecoder(resp.Body).Decode(&overall); err != nil {
+		t.Fatalf("decode /health/status body: %v", err)
+	}
+	if len(overall.Components) != 2 {
+		t.Fatalf("/health/status before any report has %d components, want 2 (both desired, never-reported): %+v", len(overall.Components), overall.Components)
+	}
+
+	hc.receptor <- ComponentStatus{comp: Storage, stat: Ready}
+	hc.receptor <- ComponentStatus{comp: ArchiveStorage, stat: Unavailable, err: fmt.Errorf("boom")}
+	waitForStatus(t, hc, Degraded, time.Second)
+
+	// Degraded must be 200 on every endpoint, including /health/status -
+	// this is the exact mismatch a maintainer flagged in review.
+	if resp := get("/health/ready"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("/health/ready while Degraded = %d, want 200", resp.StatusCode)
+	}
+	resp = get("/health/status")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/health/status while Degraded = %d, want 200", resp.StatusCode)
+	}
+	overall = overallHealthStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(&overall); err != nil {
+		t.Fatalf("decode /health/status body: %v", err)
+	}
+	if overall.Status != "degraded" {
+		t.Fatalf("/health/status body status = %q, want %q", overall.Status, "degraded")
+	}
+}
+
+type countingProber struct {
+	checks int32
+}
+
+func (p *countingProber) Check(_ context.Context) (Status, error) {
+	atomic.AddInt32(&p.checks, 1)
+	return Ready, nil
+}
+
+func TestRegisterProbe_StopsOnClose(t *testing.T) {
+	prober := &countingProber{}
+	hc := New(
+		Unavailable,
+		RegisterProbe(Storage, 5*time.Millisecond, time.Second, prober),
+	)
+
+	// Let it tick a few times.
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&prober.checks) == 0 {
+		t.Fatal("probe never ran")
+	}
+
+	if err := hc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Close may race with one in-flight tick, so give that a moment to
+	// settle before asserting the goroutine has actually stopped.
+	time.Sleep(15 * time.Millisecond)
+	after := atomic.LoadInt32(&prober.checks)
+	time.Sleep(30 * time.Millisecond)
+	if got := atomic.LoadInt32(&prober.checks); got != after {
+		t.Fatalf("probe kept running after Close: %d checks before, %d after", after, got)
+	}
+}
+
+// togglingProber reports Unavailable once failing is set, until reset.
+type togglingProber struct {
+	failing int32 // atomic
+}
+
+func (p *togglingProber) Check(_ context.Context) (Status, error) {
+	if atomic.LoadInt32(&p.failing) != 0 {
+		return Unavailable, fmt.Errorf("probe failing")
+	}
+	return Ready, nil
+}
+
+func TestRegisterProbe_Thresholds(t *testing.T) {
+	receptor := make(chan ComponentStatus, 16)
+	prober := &togglingProber{}
+	hc := New(
+		Unavailable,
+		SetReceptor(receptor),
+		SetDesired(map[Component]Criticality{Storage: Required}),
+		RegisterProbe(Storage, 50*time.Millisecond, time.Second, prober, Thresholds(1, 2)),
+	)
+	defer hc.Close()
+
+	waitForStatus(t, hc, Ready, time.Second)
+
+	atomic.StoreInt32(&prober.failing, 1)
+
+	// unhealthyThreshold is 2, so right after the first failing check (at
+	// ~1 interval in) the aggregate must still be Ready; only the second
+	// consecutive failure (~2 intervals in) should flip it.
+	time.Sleep(80 * time.Millisecond)
+	if got := hc.Get(); got != Ready {
+		t.Fatalf("flipped to %s after only one failing check, want still Ready (unhealthyThreshold=2)", got)
+	}
+
+	waitForStatus(t, hc, Unavailable, time.Second)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Prober performs an active health check against a dependency, instead of
+// waiting for that dependency to self-report through a StatusReporter.
+type Prober interface {
+	Check(ctx context.Context) (Status, error)
+}
+
+// proberRegistration is the state backing a single RegisterProbe call.
+type proberRegistration struct {
+	comp     Component
+	interval time.Duration
+	timeout  time.Duration
+	prober   Prober
+
+	healthyThreshold   int
+	unhealthyThreshold int
+}
+
+// runProbe runs reg.prober on reg.interval until hc.probeDone is closed by
+// closeServers, reporting its result through the normal GetStatusReporter
+// path once the configured consecutive-result threshold is met.
+func (hc *HealthCheck) runProbe(reg *proberRegistration) {
+	report := hc.GetStatusReporter(reg.comp)
+	ticker := time.NewTicker(reg.interval)
+	defer ticker.Stop()
+
+	var consecutiveHealthy, consecutiveUnhealthy int
+	for {
+		select {
+		case <-hc.probeDone:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), reg.timeout)
+		stat, err := reg.prober.Check(ctx)
+		cancel()
+
+		if err == nil && stat == Ready {
+			consecutiveUnhealthy = 0
+			consecutiveHealthy++
+			if consecutiveHealthy >= reg.healthyThreshold {
+				report(Ready, nil)
+			}
+			continue
+		}
+		consecutiveHealthy = 0
+		consecutiveUnhealthy++
+		if consecutiveUnhealthy >= reg.unhealthyThreshold {
+			report(Unavailable, err)
+		}
+	}
+}
+
+// SQLProber is a Prober that checks an *sql.DB via PingContext, for wiring
+// up a SQL-backed storage plugin with one RegisterProbe call.
+type SQLProber struct {
+	DB *sql.DB
+}
+
+// Check implements Prober.
+func (p *SQLProber) Check(ctx context.Context) (Status, error) {
+	if err := p.DB.PingContext(ctx); err != nil {
+		return Unavailable, err
+	}
+	return Ready, nil
+}
+
+// HTTPProber is a Prober that issues a GET request against URL and
+// considers any 2xx response healthy.
+type HTTPProber struct {
+	URL    string
+	Client *http.Client
+}
+
+// Check implements Prober.
+func (p *HTTPProber) Check(ctx context.Context) (Status, error) {
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return Unavailable, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Unavailable, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Unavailable, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, p.URL)
+	}
+	return Ready, nil
+}
+
+// GRPCProber is a Prober that calls the grpc.health.v1 Health service of a
+// remote dependency over an already-established connection.
+type GRPCProber struct {
+	Conn    *grpc.ClientConn
+	Service string
+}
+
+// Check implements Prober.
+func (p *GRPCProber) Check(ctx context.Context) (Status, error) {
+	resp, err := grpc_health_v1.NewHealthClient(p.Conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: p.Service})
+	if err != nil {
+		return Unavailable, err
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return Unavailable, fmt.Errorf("dependency %q reported status %s", p.Service, resp.Status)
+	}
+	return Ready, nil
+}